@@ -0,0 +1,195 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// archiveStem strips the archive extension (including the double
+// extension of .tar.gz/.tar.bz2) from a filename, and reports whether
+// the file was recognized as an archive at all.
+func archiveStem(fpath string) (stem string, ok bool) {
+	name := fpath
+	ext := strings.ToLower(filepath.Ext(name))
+
+	switch ext {
+	case ".zip", ".tar", ".tgz":
+		return strings.TrimSuffix(name, filepath.Ext(name)), true
+	case ".gz", ".bz2":
+		stem = strings.TrimSuffix(name, filepath.Ext(name))
+		if strings.ToLower(filepath.Ext(stem)) == ".tar" {
+			return strings.TrimSuffix(stem, filepath.Ext(stem)), true
+		}
+		return "", false
+	default:
+		return "", false
+	}
+}
+
+// archiveDestDir returns the sibling directory an archive would be
+// extracted into.
+func archiveDestDir(fpath string) (string, bool) {
+	stem, ok := archiveStem(fpath)
+	if !ok {
+		return "", false
+	}
+	return stem, true
+}
+
+// extractArchive unpacks a downloaded archive into a sibling directory
+// named after the archive stem, dispatching on the extension chain.
+// When del is true, the archive is removed after successful extraction.
+func extractArchive(fpath string, del bool) error {
+	destDir, ok := archiveDestDir(fpath)
+	if !ok {
+		return nil
+	}
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return err
+	}
+
+	ext := strings.ToLower(filepath.Ext(fpath))
+	var err error
+	switch {
+	case ext == ".zip":
+		err = extractZip(fpath, destDir)
+	case ext == ".tar":
+		err = extractTarFile(fpath, destDir, nil)
+	case ext == ".tgz" || strings.HasSuffix(strings.ToLower(fpath), ".tar.gz"):
+		err = extractTarFile(fpath, destDir, func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		})
+	case strings.HasSuffix(strings.ToLower(fpath), ".tar.bz2"):
+		err = extractTarFile(fpath, destDir, func(r io.Reader) (io.Reader, error) {
+			return bzip2.NewReader(r), nil
+		})
+	default:
+		return fmt.Errorf("unsupported archive type: %s", fpath)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if del {
+		return os.Remove(fpath)
+	}
+	return nil
+}
+
+func extractZip(fpath, destDir string) error {
+	r, err := zip.OpenReader(fpath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		target, err := safeJoin(destDir, f.Name)
+		if err != nil {
+			return err
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, f.Mode()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = writeEntry(target, rc, f.Mode())
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decompressorFunc wraps a compressed tar stream (gzip, bzip2) in a
+// plain io.Reader; nil means the tar is not further compressed.
+type decompressorFunc func(io.Reader) (io.Reader, error)
+
+func extractTarFile(fpath, destDir string, decompress decompressorFunc) error {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if decompress != nil {
+		r, err = decompress(f)
+		if err != nil {
+			return err
+		}
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := safeJoin(destDir, hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(hdr.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := writeEntry(target, tr, os.FileMode(hdr.Mode)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// safeJoin joins name onto destDir and rejects entries whose cleaned
+// path would escape destDir, guarding against zip/tar path traversal.
+func safeJoin(destDir, name string) (string, error) {
+	target := filepath.Join(destDir, name)
+	if !strings.HasPrefix(target, filepath.Clean(destDir)+string(os.PathSeparator)) && target != filepath.Clean(destDir) {
+		return "", fmt.Errorf("illegal file path in archive: %s", name)
+	}
+	return target, nil
+}
+
+func writeEntry(target string, r io.Reader, mode os.FileMode) error {
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, r)
+	return err
+}
@@ -0,0 +1,65 @@
+package main
+
+import "testing"
+
+func TestArchiveStem(t *testing.T) {
+	tests := []struct {
+		name     string
+		fpath    string
+		wantStem string
+		wantOK   bool
+	}{
+		{"zip", "/out/release.zip", "/out/release", true},
+		{"tar", "/out/release.tar", "/out/release", true},
+		{"tgz", "/out/release.tgz", "/out/release", true},
+		{"tar.gz", "/out/release.tar.gz", "/out/release", true},
+		{"tar.bz2", "/out/release.tar.bz2", "/out/release", true},
+		{"bare gz", "/out/release.gz", "", false},
+		{"bare bz2", "/out/release.bz2", "", false},
+		{"unrecognized", "/out/release.pdf", "", false},
+		{"no extension", "/out/release", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stem, ok := archiveStem(tt.fpath)
+			if ok != tt.wantOK || stem != tt.wantStem {
+				t.Errorf("archiveStem(%q) = (%q, %v), want (%q, %v)", tt.fpath, stem, ok, tt.wantStem, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSafeJoin(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   string
+		want    string
+		wantErr bool
+	}{
+		{"plain file", "file.txt", "/out/file.txt", false},
+		{"nested dir", "sub/dir/file.txt", "/out/sub/dir/file.txt", false},
+		{"dot-dot escape", "../escape.txt", "", true},
+		{"nested dot-dot escape", "sub/../../escape.txt", "", true},
+		{"absolute path", "/etc/passwd", "/out/etc/passwd", false},
+		{"destDir itself", ".", "/out", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := safeJoin("/out", tt.entry)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("safeJoin(%q) = %q, want error", tt.entry, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("safeJoin(%q) returned unexpected error: %v", tt.entry, err)
+			}
+			if got != tt.want {
+				t.Errorf("safeJoin(%q) = %q, want %q", tt.entry, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// crawl turns the single-page extractor into a bounded site harvester:
+// it fetches config.URL, then follows <a href> links up to config.Depth
+// pages deep, scoped by --same-host and --follow, filtered by robots.txt
+// (unless --ignore-robots) and throttled per host by --delay. Each page
+// is fetched in its own goroutine as soon as it is discovered (at most
+// config.Parallel at a time), so pages are not visited in breadth-first
+// order; a page is fetched once, at the depth it was first reached at.
+func crawl(config Config) ([]FileLink, error) {
+	startURL, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var followPattern *regexp.Regexp
+	if config.Follow != "" {
+		followPattern, err = regexp.Compile(config.Follow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid follow pattern: %v", err)
+		}
+	}
+
+	robots := newRobotsCache(config.UserAgent)
+
+	visited := make(map[string]bool)
+	var visitedMu sync.Mutex
+
+	var resultsMu sync.Mutex
+	var results []FileLink
+	seenFile := make(map[string]bool)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, config.Parallel)
+
+	var enqueue func(u *url.URL, depth int)
+	enqueue = func(u *url.URL, depth int) {
+		key := canonicalizeURL(u)
+		visitedMu.Lock()
+		if visited[key] {
+			visitedMu.Unlock()
+			return
+		}
+		visited[key] = true
+		visitedMu.Unlock()
+
+		if config.SameHost && u.Host != startURL.Host {
+			return
+		}
+		if followPattern != nil && depth > 0 && !followPattern.MatchString(u.String()) {
+			return
+		}
+		if !config.IgnoreRobots && !robots.allowed(u) {
+			return
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			robots.throttle(u.Host, config.Delay)
+
+			fileLinks, pageLinks, err := fetchPage(config, u.String())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error crawling %s: %v\n", u.String(), err)
+				return
+			}
+
+			resultsMu.Lock()
+			for _, fl := range fileLinks {
+				if !seenFile[fl.URL] {
+					seenFile[fl.URL] = true
+					results = append(results, fl)
+				}
+			}
+			resultsMu.Unlock()
+
+			if depth >= config.Depth {
+				return
+			}
+			for _, link := range pageLinks {
+				next, err := url.Parse(link)
+				if err != nil {
+					continue
+				}
+				enqueue(next, depth+1)
+			}
+		}()
+	}
+
+	enqueue(startURL, 0)
+	wg.Wait()
+
+	return results, nil
+}
+
+// canonicalizeURL keys the visited set by scheme+host+path with a
+// sorted, normalized query, so that equivalent URLs (differing only in
+// query parameter order, or a trailing fragment) aren't crawled twice.
+func canonicalizeURL(u *url.URL) string {
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var q strings.Builder
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			if q.Len() > 0 {
+				q.WriteByte('&')
+			}
+			q.WriteString(k)
+			q.WriteByte('=')
+			q.WriteString(v)
+		}
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host) + path + "?" + q.String()
+}
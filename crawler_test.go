@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestCanonicalizeURL(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		same bool
+	}{
+		{"identical", "https://example.com/page", "https://example.com/page", true},
+		{"query order differs", "https://example.com/page?b=2&a=1", "https://example.com/page?a=1&b=2", true},
+		{"trailing slash differs", "https://example.com/page/", "https://example.com/page", true},
+		{"scheme case differs", "HTTPS://example.com/page", "https://example.com/page", true},
+		{"host case differs", "https://Example.com/page", "https://example.com/page", true},
+		{"different path", "https://example.com/page", "https://example.com/other", false},
+		{"different query value", "https://example.com/page?a=1", "https://example.com/page?a=2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ua, err := url.Parse(tt.a)
+			if err != nil {
+				t.Fatalf("url.Parse(%q): %v", tt.a, err)
+			}
+			ub, err := url.Parse(tt.b)
+			if err != nil {
+				t.Fatalf("url.Parse(%q): %v", tt.b, err)
+			}
+
+			got := canonicalizeURL(ua) == canonicalizeURL(ub)
+			if got != tt.same {
+				t.Errorf("canonicalizeURL(%q) == canonicalizeURL(%q) = %v, want %v", tt.a, tt.b, got, tt.same)
+			}
+		})
+	}
+}
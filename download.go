@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// downloadFile fetches url into filepath, resuming a previous partial
+// download if one is present, retrying with exponential backoff on
+// failure. When expectedSHA256 is non-empty, the downloaded content is
+// verified against it before the file is kept. A file:// URL (as
+// produced by the file and git sources) is copied locally instead of
+// fetched over HTTP.
+func downloadFile(client *http.Client, url, filepath, userAgent, expectedSHA256 string, retries int) error {
+	if strings.HasPrefix(url, "file://") {
+		return copyLocalFile(strings.TrimPrefix(url, "file://"), filepath, expectedSHA256)
+	}
+
+	var lastErr error
+	backoff := time.Second
+
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		if lastErr = attemptDownload(client, url, filepath, userAgent, expectedSHA256); lastErr == nil {
+			return nil
+		}
+	}
+
+	return lastErr
+}
+
+// copyLocalFile copies a local source path (from a file:// URL) to
+// filepath, verifying expectedSHA256 when given.
+func copyLocalFile(srcPath, filepath, expectedSHA256 string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	out, err := os.Create(filepath)
+	if err != nil {
+		return err
+	}
+
+	var hasher hash.Hash
+	var writer io.Writer = out
+	if expectedSHA256 != "" {
+		hasher = sha256.New()
+		writer = io.MultiWriter(out, hasher)
+	}
+
+	_, copyErr := io.Copy(writer, src)
+	out.Close()
+	if copyErr != nil {
+		return copyErr
+	}
+
+	if hasher != nil {
+		sum := hex.EncodeToString(hasher.Sum(nil))
+		if !strings.EqualFold(sum, expectedSHA256) {
+			os.Remove(filepath)
+			return fmt.Errorf("checksum mismatch: got %s, want %s", sum, expectedSHA256)
+		}
+	}
+
+	return nil
+}
+
+// attemptDownload makes a single download attempt, resuming from
+// <filepath>.part via a Range request when that file already exists.
+func attemptDownload(client *http.Client, rawURL, fpath, userAgent, expectedSHA256 string) error {
+	partPath := fpath + ".part"
+
+	var startOffset int64
+	if info, err := os.Stat(partPath); err == nil {
+		startOffset = info.Size()
+	}
+
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if startOffset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", startOffset))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var out *os.File
+	resumed := false
+	switch resp.StatusCode {
+	case http.StatusOK:
+		out, err = os.Create(partPath)
+	case http.StatusPartialContent:
+		out, err = os.OpenFile(partPath, os.O_APPEND|os.O_WRONLY, 0644)
+		resumed = true
+	default:
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if err != nil {
+		return err
+	}
+
+	var hasher hash.Hash
+	var writer io.Writer = out
+	if expectedSHA256 != "" {
+		hasher = sha256.New()
+		if resumed {
+			if existing, err := os.Open(partPath); err == nil {
+				io.Copy(hasher, existing)
+				existing.Close()
+			}
+		}
+		writer = io.MultiWriter(out, hasher)
+	}
+
+	_, copyErr := io.Copy(writer, resp.Body)
+	out.Close()
+	if copyErr != nil {
+		return copyErr
+	}
+
+	if hasher != nil {
+		sum := hex.EncodeToString(hasher.Sum(nil))
+		if !strings.EqualFold(sum, expectedSHA256) {
+			os.Remove(partPath)
+			return fmt.Errorf("checksum mismatch: got %s, want %s", sum, expectedSHA256)
+		}
+	}
+
+	return os.Rename(partPath, fpath)
+}
+
+// loadChecksums reads a sha256sum-format file (lines of "<hex>  <name>")
+// into a map keyed by basename.
+func loadChecksums(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		name := strings.TrimPrefix(strings.Join(fields[1:], " "), "*")
+		checksums[filepath.Base(name)] = strings.ToLower(fields[0])
+	}
+
+	return checksums, scanner.Err()
+}
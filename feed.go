@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// mimeExtensions maps common enclosure MIME types to a file extension,
+// since podcast/feed enclosure URLs frequently lack one.
+var mimeExtensions = map[string]string{
+	"audio/mpeg":      "mp3",
+	"audio/mp3":       "mp3",
+	"audio/mp4":       "m4a",
+	"audio/x-m4a":     "m4a",
+	"audio/wav":       "wav",
+	"audio/x-wav":     "wav",
+	"audio/ogg":       "ogg",
+	"video/mp4":       "mp4",
+	"video/quicktime": "mov",
+	"application/pdf": "pdf",
+	"application/zip": "zip",
+}
+
+// rssFeed and its nested types model just enough of RSS 2.0 to pull
+// enclosures out of each item.
+type rssFeed struct {
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	Title     string `xml:"title"`
+	PubDate   string `xml:"pubDate"`
+	Enclosure struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+}
+
+// atomFeed models just enough of Atom to pull rel="enclosure" links
+// out of each entry.
+type atomFeed struct {
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title     string     `xml:"title"`
+	Published string     `xml:"published"`
+	Links     []atomLink `xml:"link"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// isFeedMode decides whether the response should be parsed as a feed
+// rather than as HTML, honoring an explicit --feed override.
+func isFeedMode(feedFlag, contentType string) bool {
+	switch feedFlag {
+	case "rss", "atom":
+		return true
+	case "html":
+		return false
+	}
+
+	contentType = strings.ToLower(contentType)
+	return strings.Contains(contentType, "application/rss+xml") ||
+		strings.Contains(contentType, "application/atom+xml") ||
+		strings.Contains(contentType, "text/xml") ||
+		strings.Contains(contentType, "application/xml")
+}
+
+// extractFeedLinks parses an RSS or Atom feed and returns its enclosures
+// as FileLinks, in place of the usual <a href> walk.
+func extractFeedLinks(body io.Reader, config Config) ([]FileLink, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []FileLink
+	var seq int
+
+	addLink := func(title, enclosureURL, mimeType, dateStr string) {
+		if enclosureURL == "" {
+			return
+		}
+
+		name := sanitizeFilename(title)
+		if name == "" || name == "unnamed" {
+			name = "unnamed"
+		}
+
+		if ext := extensionForMIME(mimeType, enclosureURL); ext != "" && !strings.HasSuffix(strings.ToLower(name), ext) {
+			name += ext
+		}
+
+		prefix := ""
+		if pub, err := parseFeedDate(dateStr); err == nil {
+			prefix = pub.Format("2006-01-02") + "_"
+		} else if config.Seq {
+			seq++
+			prefix = fmt.Sprintf("%04d_", seq)
+		}
+
+		links = append(links, FileLink{Name: prefix + name, URL: enclosureURL})
+	}
+
+	if config.Feed == "atom" || (config.Feed == "auto" && looksLikeAtom(data)) {
+		var feed atomFeed
+		if err := xml.Unmarshal(data, &feed); err != nil {
+			return nil, fmt.Errorf("parsing atom feed: %v", err)
+		}
+		for _, entry := range feed.Entries {
+			for _, l := range entry.Links {
+				if l.Rel == "enclosure" {
+					addLink(entry.Title, l.Href, l.Type, entry.Published)
+				}
+			}
+		}
+		return links, nil
+	}
+
+	var feed rssFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, fmt.Errorf("parsing rss feed: %v", err)
+	}
+	for _, item := range feed.Channel.Items {
+		addLink(item.Title, item.Enclosure.URL, item.Enclosure.Type, item.PubDate)
+	}
+
+	return links, nil
+}
+
+func looksLikeAtom(data []byte) bool {
+	return strings.Contains(string(data), "<feed")
+}
+
+// extensionForMIME derives a file extension from an enclosure's MIME
+// type, falling back to whatever extension (if any) the URL already has.
+func extensionForMIME(mimeType, rawURL string) string {
+	mimeType = strings.ToLower(strings.TrimSpace(strings.SplitN(mimeType, ";", 2)[0]))
+	if ext, ok := mimeExtensions[mimeType]; ok {
+		return "." + ext
+	}
+
+	if u, err := url.Parse(rawURL); err == nil {
+		if ext := filepath.Ext(u.Path); ext != "" {
+			return ext
+		}
+	}
+
+	return ""
+}
+
+// parseFeedDate tries the date formats used by RSS (pubDate) and Atom
+// (published) in turn.
+func parseFeedDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+
+	formats := []string{
+		time.RFC1123Z,
+		time.RFC1123,
+		time.RFC3339,
+	}
+	for _, f := range formats {
+		if t, err := time.Parse(f, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date format: %q", s)
+}
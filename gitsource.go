@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// gitSource shallow-clones the repository into a temp directory and
+// enumerates matching files the same way the file source does,
+// mirroring how `git::` prefixes are dispatched in other Go download
+// tools (e.g. go-getter).
+type gitSource struct{}
+
+func (gitSource) Fetch(config Config, u *url.URL) ([]FileLink, error) {
+	if u.Scheme == "" || strings.HasPrefix(u.String(), "-") {
+		return nil, fmt.Errorf("git source requires a real transport URL, got %q", u.String())
+	}
+
+	tmpDir, err := os.MkdirTemp("", "link-dl-git-")
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("git", "clone", "--depth", "1", "--", u.String(), tmpDir)
+	cmd.Stdout = io.Discard
+	cmd.Stderr = io.Discard
+	if err := cmd.Run(); err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("git clone %s: %v", u.String(), err)
+	}
+
+	// The clone must outlive this call (downloadAll still needs to read
+	// the files it contains), so register it for cleanup once main is
+	// done with the FileLinks instead of removing it here.
+	registerTempDir(tmpDir)
+
+	return fileSource{}.Fetch(config, &url.URL{Scheme: "file", Path: tmpDir})
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"flag"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"os"
@@ -22,14 +21,26 @@ type FileLink struct {
 }
 
 type Config struct {
-	URL       string
-	OutDir    string
-	Parallel  int
-	Exts      []string
-	All       bool
-	Include   string
-	ListOnly  bool
-	UserAgent string
+	URL           string
+	OutDir        string
+	Parallel      int
+	Exts          []string
+	All           bool
+	Include       string
+	ListOnly      bool
+	UserAgent     string
+	Feed          string
+	Seq           bool
+	Extract       bool
+	ExtractDelete bool
+	Depth         int
+	SameHost      bool
+	Follow        string
+	IgnoreRobots  bool
+	Delay         time.Duration
+	Checksums     string
+	Retries       int
+	SourceList    bool
 }
 
 const defaultUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
@@ -37,17 +48,24 @@ const defaultUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleW
 func main() {
 	config := parseFlags()
 
+	if config.SourceList {
+		printSourceList()
+		return
+	}
+
 	if config.URL == "" {
 		printUsage()
 		os.Exit(1)
 	}
 
 	// Extract links
-	links, err := extractLinks(config)
+	links, err := extractLinksForURL(config)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error extracting links: %v\n", err)
+		cleanupTempDirs()
 		os.Exit(1)
 	}
+	defer cleanupTempDirs()
 
 	if len(links) == 0 {
 		fmt.Println("No matching files found.")
@@ -82,6 +100,18 @@ func parseFlags() Config {
 	include := flag.String("include", "", "Regex pattern to filter URLs")
 	listOnly := flag.Bool("list", false, "List files only, don't download")
 	userAgent := flag.String("ua", defaultUserAgent, "User-Agent header")
+	feed := flag.String("feed", "auto", "Feed mode: auto|rss|atom|html")
+	seq := flag.Bool("seq", false, "Prefix feed items with a sequence number when no date is available")
+	extract := flag.Bool("extract", false, "Extract downloaded archives (.zip, .tar, .tar.gz, .tar.bz2)")
+	extractDelete := flag.Bool("extract-delete", false, "Delete archives after successful extraction")
+	depth := flag.Int("depth", 0, "Crawl links up to this many pages deep (0 = single page)")
+	sameHost := flag.Bool("same-host", false, "Only follow links on the same host as the starting URL")
+	follow := flag.String("follow", "", "Regex pattern that crawled links must match to be traversed")
+	ignoreRobots := flag.Bool("ignore-robots", false, "Ignore robots.txt when crawling")
+	delay := flag.Duration("delay", 0, "Minimum delay between requests to the same host when crawling")
+	checksums := flag.String("checksums", "", "Path to a sha256sum-format file to verify downloads against")
+	retries := flag.Int("retries", 0, "Number of retry attempts for a failed download, with exponential backoff")
+	sourceList := flag.Bool("source-list", false, "Print registered URL source schemes and exit")
 
 	flag.Usage = printUsage
 	flag.Parse()
@@ -103,14 +133,26 @@ func parseFlags() Config {
 	}
 
 	return Config{
-		URL:       targetURL,
-		OutDir:    *outDir,
-		Parallel:  *parallel,
-		Exts:      extensions,
-		All:       *all,
-		Include:   *include,
-		ListOnly:  *listOnly,
-		UserAgent: *userAgent,
+		URL:           targetURL,
+		OutDir:        *outDir,
+		Parallel:      *parallel,
+		Exts:          extensions,
+		All:           *all,
+		Include:       *include,
+		ListOnly:      *listOnly,
+		UserAgent:     *userAgent,
+		Feed:          strings.ToLower(*feed),
+		Seq:           *seq,
+		Extract:       *extract,
+		ExtractDelete: *extractDelete,
+		Depth:         *depth,
+		SameHost:      *sameHost,
+		Follow:        *follow,
+		IgnoreRobots:  *ignoreRobots,
+		Delay:         *delay,
+		Checksums:     *checksums,
+		Retries:       *retries,
+		SourceList:    *sourceList,
 	}
 }
 
@@ -126,56 +168,92 @@ Examples:
   link-dl "https://example.com/page" --all
   link-dl "https://example.com/page" --list
   link-dl "https://example.com/page" --include "2024.*\.pdf"
+  link-dl --feed rss "https://example.com/podcast.xml"
+  link-dl --ext zip,gz --extract "https://example.com/releases"
+  link-dl --depth 2 --same-host --delay 500ms "https://example.com"
+  link-dl --checksums sha256sums.txt --retries 3 "https://example.com/files"
+  link-dl --ext md "git::https://github.com/foo/bar"
+  link-dl --ext pdf "sitemap+https://example.com/sitemap.xml"
+  link-dl --source-list
 
 Options:`)
 	flag.PrintDefaults()
 }
 
+// extractLinks is the entry point of the http/https source: a plain
+// single-page scrape, or (when --depth is set) a bounded crawl that
+// fans out across pages via crawl.
 func extractLinks(config Config) ([]FileLink, error) {
+	if config.Depth > 0 {
+		return crawl(config)
+	}
+
+	links, _, err := fetchPage(config, config.URL)
+	return links, err
+}
+
+// htmlishExts are extensions (or the lack of one) that a crawled <a
+// href> is allowed to point at for further traversal. Checking the
+// extension avoids an extra HEAD request per candidate link just to
+// read its real Content-Type.
+var htmlishExts = map[string]bool{
+	"":      true,
+	".html": true,
+	".htm":  true,
+	".php":  true,
+	".asp":  true,
+	".aspx": true,
+	".jsp":  true,
+}
+
+// fetchPage downloads a single page and returns both the file links it
+// matches and, when crawling is enabled, the further <a href> links
+// worth traversing.
+func fetchPage(config Config, pageURL string) ([]FileLink, []string, error) {
 	client := &http.Client{
 		Timeout: 30 * time.Second,
 	}
 
-	req, err := http.NewRequest("GET", config.URL, nil)
+	req, err := http.NewRequest("GET", pageURL, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	req.Header.Set("User-Agent", config.UserAgent)
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+		return nil, nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	if isFeedMode(config.Feed, resp.Header.Get("Content-Type")) {
+		links, err := extractFeedLinks(resp.Body, config)
+		return links, nil, err
 	}
 
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	baseURL, err := url.Parse(config.URL)
+	baseURL, err := url.Parse(pageURL)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var includePattern *regexp.Regexp
-	if config.Include != "" {
-		includePattern, err = regexp.Compile(config.Include)
-		if err != nil {
-			return nil, fmt.Errorf("invalid include pattern: %v", err)
-		}
+	filter, err := newFileFilter(config)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	var links []FileLink
+	var pageLinks []string
 	seen := make(map[string]bool)
 
-	// Common file extensions for --all mode
-	fileExtPattern := regexp.MustCompile(`(?i)\.(pdf|docx?|xlsx?|xlsm|pptx?|csv|txt|zip|rar|7z|tar|gz|jpg|jpeg|png|gif|svg|mp3|mp4|wav|avi|mov)$`)
-
 	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
 		href, exists := s.Attr("href")
 		if !exists || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
@@ -189,34 +267,16 @@ func extractLinks(config Config) ([]FileLink, error) {
 		}
 
 		fullURL := linkURL.String()
-		if seen[fullURL] {
-			return
-		}
-
-		// Check extension
 		ext := strings.ToLower(filepath.Ext(linkURL.Path))
-		
-		if config.All {
-			// In --all mode, match common file extensions
-			if !fileExtPattern.MatchString(linkURL.Path) {
-				return
-			}
-		} else {
-			// Check against specified extensions
-			matched := false
-			for _, e := range config.Exts {
-				if ext == e {
-					matched = true
-					break
-				}
-			}
-			if !matched {
-				return
+
+		if !filter.matches(linkURL.Path, fullURL) {
+			if config.Depth > 0 && htmlishExts[ext] {
+				pageLinks = append(pageLinks, fullURL)
 			}
+			return
 		}
 
-		// Check include pattern
-		if includePattern != nil && !includePattern.MatchString(fullURL) {
+		if seen[fullURL] {
 			return
 		}
 
@@ -240,7 +300,7 @@ func extractLinks(config Config) ([]FileLink, error) {
 		links = append(links, FileLink{Name: name, URL: fullURL})
 	})
 
-	return links, nil
+	return links, pageLinks, nil
 }
 
 func sanitizeFilename(name string) string {
@@ -279,6 +339,15 @@ func downloadAll(links []FileLink, config Config) {
 		Timeout: 5 * time.Minute,
 	}
 
+	var checksums map[string]string
+	if config.Checksums != "" {
+		var err error
+		checksums, err = loadChecksums(config.Checksums)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading checksums file: %v\n", err)
+		}
+	}
+
 	successCount := 0
 	failCount := 0
 
@@ -295,17 +364,27 @@ func downloadAll(links []FileLink, config Config) {
 			mu.Unlock()
 
 			fpath := filepath.Join(config.OutDir, filename)
+			expectedSHA256 := checksums[filepath.Base(link.Name)]
 
-			err := downloadFile(client, link.URL, fpath, config.UserAgent)
+			err := downloadFile(client, link.URL, fpath, config.UserAgent, expectedSHA256, config.Retries)
 			mu.Lock()
 			if err != nil {
 				fmt.Printf("✗ %s: %v\n", filename, err)
 				failCount++
-			} else {
-				fmt.Printf("✓ %s\n", filename)
-				successCount++
+				mu.Unlock()
+				return
 			}
+			fmt.Printf("✓ %s\n", filename)
+			successCount++
 			mu.Unlock()
+
+			if config.Extract {
+				if err := extractArchive(fpath, config.ExtractDelete); err != nil {
+					fmt.Printf("  ✗ extract %s: %v\n", filename, err)
+				} else if dest, ok := archiveDestDir(fpath); ok {
+					fmt.Printf("  ✓ extracted to %s\n", dest)
+				}
+			}
 		}(link)
 	}
 
@@ -336,30 +415,3 @@ func getUniqueFilename(dir, name string, usedNames map[string]int) string {
 		}
 	}
 }
-
-func downloadFile(client *http.Client, url, filepath, userAgent string) error {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return err
-	}
-	req.Header.Set("User-Agent", userAgent)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP %d", resp.StatusCode)
-	}
-
-	out, err := os.Create(filepath)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	_, err = io.Copy(out, resp.Body)
-	return err
-}
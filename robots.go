@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// robotsData holds the Disallow prefixes that apply to us, fetched
+// from a single host's /robots.txt.
+type robotsData struct {
+	disallow []string
+}
+
+// robotsCache fetches and caches robotsData per host, and enforces a
+// minimum delay between requests to the same host.
+type robotsCache struct {
+	userAgent string
+	client    *http.Client
+
+	mu    sync.Mutex
+	data  map[string]*robotsData
+	last  map[string]time.Time
+	hostM map[string]*sync.Mutex
+}
+
+func newRobotsCache(userAgent string) *robotsCache {
+	return &robotsCache{
+		userAgent: userAgent,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		data:      make(map[string]*robotsData),
+		last:      make(map[string]time.Time),
+		hostM:     make(map[string]*sync.Mutex),
+	}
+}
+
+// allowed reports whether u may be fetched per the cached robots.txt
+// for its host.
+func (c *robotsCache) allowed(u *url.URL) bool {
+	data := c.get(u)
+	for _, prefix := range data.disallow {
+		if prefix != "" && strings.HasPrefix(u.Path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *robotsCache) get(u *url.URL) *robotsData {
+	c.mu.Lock()
+	if d, ok := c.data[u.Host]; ok {
+		c.mu.Unlock()
+		return d
+	}
+	c.mu.Unlock()
+
+	data := c.fetch(u)
+
+	c.mu.Lock()
+	c.data[u.Host] = data
+	c.mu.Unlock()
+
+	return data
+}
+
+func (c *robotsCache) fetch(u *url.URL) *robotsData {
+	robotsURL := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+
+	req, err := http.NewRequest("GET", robotsURL.String(), nil)
+	if err != nil {
+		return &robotsData{}
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return &robotsData{}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &robotsData{}
+	}
+
+	return parseRobotsTxt(resp.Body)
+}
+
+// parseRobotsTxt extracts Disallow rules that apply to the "*" user
+// agent group. It doesn't attempt to match specific bot names: for a
+// crawler like this one, treating the wildcard group as authoritative
+// is the conservative, widely-used approximation.
+func parseRobotsTxt(r io.Reader) *robotsData {
+	data := &robotsData{}
+	scanner := bufio.NewScanner(r)
+
+	inWildcardGroup := false
+	lastWasAgent := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		field := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(parts[1])
+
+		switch field {
+		case "user-agent":
+			// Consecutive User-agent lines share one group of rules.
+			if !lastWasAgent {
+				inWildcardGroup = false
+			}
+			if value == "*" {
+				inWildcardGroup = true
+			}
+		case "disallow":
+			if inWildcardGroup && value != "" {
+				data.disallow = append(data.disallow, value)
+			}
+		}
+		lastWasAgent = field == "user-agent"
+	}
+
+	return data
+}
+
+// throttle blocks until at least delay has passed since the last
+// request to host, so that request starts to one host are spaced at
+// least delay apart. The host is not held while the request itself
+// runs, so requests to the same host can overlap; with a delay of 0
+// throttle does not wait at all.
+func (c *robotsCache) throttle(host string, delay time.Duration) {
+	c.mu.Lock()
+	m, ok := c.hostM[host]
+	if !ok {
+		m = &sync.Mutex{}
+		c.hostM[host] = m
+	}
+	c.mu.Unlock()
+
+	m.Lock()
+	defer m.Unlock()
+
+	if delay <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	last, seen := c.last[host]
+	c.mu.Unlock()
+
+	if seen {
+		if wait := delay - time.Since(last); wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+
+	c.mu.Lock()
+	c.last[host] = time.Now()
+	c.mu.Unlock()
+}
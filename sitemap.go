@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxSitemapDepth bounds recursion through nested <sitemapindex> files.
+const maxSitemapDepth = 5
+
+// sitemapURLSet is a <urlset>: a flat list of page/file locations.
+type sitemapURLSet struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+}
+
+// sitemapIndex is a <sitemapindex>: a list of other sitemaps to fetch.
+type sitemapIndex struct {
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// sitemapSource parses a sitemap (and, recursively, any sitemap index
+// it references) and treats each <loc> as a candidate file URL.
+type sitemapSource struct{}
+
+func (s sitemapSource) Fetch(config Config, u *url.URL) ([]FileLink, error) {
+	return s.fetch(config, u.String(), 0)
+}
+
+func (s sitemapSource) fetch(config Config, sitemapURL string, depth int) ([]FileLink, error) {
+	if depth > maxSitemapDepth {
+		return nil, fmt.Errorf("sitemap recursion exceeded %d levels at %s", maxSitemapDepth, sitemapURL)
+	}
+
+	data, err := fetchSitemapBody(config, sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var index sitemapIndex
+	if xml.Unmarshal(data, &index) == nil && len(index.Sitemaps) > 0 {
+		var links []FileLink
+		for _, sm := range index.Sitemaps {
+			loc := strings.TrimSpace(sm.Loc)
+			if loc == "" {
+				continue
+			}
+			sub, err := s.fetch(config, loc, depth+1)
+			if err != nil {
+				fmt.Printf("  ✗ sitemap %s: %v\n", loc, err)
+				continue
+			}
+			links = append(links, sub...)
+		}
+		return links, nil
+	}
+
+	var set sitemapURLSet
+	if err := xml.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parsing sitemap: %v", err)
+	}
+
+	return filterSitemapLocs(config, set)
+}
+
+func fetchSitemapBody(config Config, sitemapURL string) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	req, err := http.NewRequest("GET", sitemapURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", config.UserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func filterSitemapLocs(config Config, set sitemapURLSet) ([]FileLink, error) {
+	filter, err := newFileFilter(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []FileLink
+	for _, entry := range set.URLs {
+		loc := strings.TrimSpace(entry.Loc)
+		if loc == "" {
+			continue
+		}
+
+		locURL, err := url.Parse(loc)
+		if err != nil {
+			continue
+		}
+
+		if !filter.matches(locURL.Path, loc) {
+			continue
+		}
+
+		links = append(links, FileLink{Name: sanitizeFilename(filepath.Base(locURL.Path)), URL: loc})
+	}
+
+	return links, nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Source fetches candidate file links for a URL. Each scheme (or
+// scheme prefix, such as git:: or sitemap+) is backed by a Source
+// registered in sourceRegistry.
+type Source interface {
+	Fetch(config Config, u *url.URL) ([]FileLink, error)
+}
+
+var sourceRegistry = map[string]Source{}
+
+// fileExtPattern matches the common file extensions recognized by
+// --all mode, shared by every source that needs to guess "is this a
+// file" without a configured --ext list.
+var fileExtPattern = regexp.MustCompile(`(?i)\.(pdf|docx?|xlsx?|xlsm|pptx?|csv|txt|zip|rar|7z|tar|gz|jpg|jpeg|png|gif|svg|mp3|mp4|wav|avi|mov)$`)
+
+// fileFilter decides whether a candidate path or URL should be treated
+// as a downloadable file, per --ext/--all and --include. It is shared
+// by the http, file, and sitemap sources so the matching rules can't
+// drift between them.
+type fileFilter struct {
+	config  Config
+	include *regexp.Regexp
+}
+
+func newFileFilter(config Config) (*fileFilter, error) {
+	f := &fileFilter{config: config}
+	if config.Include != "" {
+		var err error
+		f.include, err = regexp.Compile(config.Include)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern: %v", err)
+		}
+	}
+	return f, nil
+}
+
+// matches reports whether a candidate is a downloadable file. path is
+// used for extension matching (a filesystem path or a URL's path
+// component); matchURL is what --include is matched against, since
+// that's usually the full URL rather than just its path.
+func (f *fileFilter) matches(path, matchURL string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	isFile := false
+	if f.config.All {
+		isFile = fileExtPattern.MatchString(path)
+	} else {
+		for _, e := range f.config.Exts {
+			if ext == e {
+				isFile = true
+				break
+			}
+		}
+	}
+	if !isFile {
+		return false
+	}
+
+	if f.include != nil && !f.include.MatchString(matchURL) {
+		return false
+	}
+
+	return true
+}
+
+var (
+	tempDirsMu sync.Mutex
+	tempDirs   []string
+)
+
+// registerTempDir records a directory a Source created (e.g. a git
+// clone) so cleanupTempDirs can remove it once downloads are done.
+func registerTempDir(path string) {
+	tempDirsMu.Lock()
+	tempDirs = append(tempDirs, path)
+	tempDirsMu.Unlock()
+}
+
+// cleanupTempDirs removes every directory registered by a Source
+// during this run. Call it once the FileLinks it produced have been
+// listed or downloaded.
+func cleanupTempDirs() {
+	tempDirsMu.Lock()
+	dirs := tempDirs
+	tempDirs = nil
+	tempDirsMu.Unlock()
+
+	for _, dir := range dirs {
+		os.RemoveAll(dir)
+	}
+}
+
+func registerSource(name string, s Source) {
+	sourceRegistry[name] = s
+}
+
+func init() {
+	h := httpSource{}
+	registerSource("http", h)
+	registerSource("https", h)
+	registerSource("file", fileSource{})
+	registerSource("sitemap", sitemapSource{})
+	registerSource("git", gitSource{})
+}
+
+// extractLinksForURL parses config.URL into a source key and target
+// URL, then dispatches to the registered Source.
+func extractLinksForURL(config Config) ([]FileLink, error) {
+	key, target, err := parseSourceURL(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %v", err)
+	}
+
+	source, ok := sourceRegistry[key]
+	if !ok {
+		return nil, fmt.Errorf("no registered source for scheme %q (see --source-list)", key)
+	}
+
+	return source.Fetch(config, target)
+}
+
+// parseSourceURL splits a CLI URL argument into its source key and the
+// underlying URL the source should operate on, handling two multi-scheme
+// prefix styles in addition to plain schemes:
+//
+//   - "git::https://github.com/foo/bar" -> key "git", target "https://github.com/foo/bar"
+//   - "sitemap+https://example.com/x"   -> key "sitemap", target "https://example.com/x"
+//   - "https://example.com"             -> key "https", target unchanged
+func parseSourceURL(raw string) (key string, target *url.URL, err error) {
+	if idx := strings.Index(raw, "::"); idx > 0 && !strings.ContainsAny(raw[:idx], "/:") {
+		target, err = url.Parse(raw[idx+2:])
+		if err != nil {
+			return "", nil, err
+		}
+		return raw[:idx], target, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if i := strings.Index(u.Scheme, "+"); i >= 0 {
+		key = u.Scheme[:i]
+		u.Scheme = u.Scheme[i+1:]
+		return key, u, nil
+	}
+
+	return u.Scheme, u, nil
+}
+
+func printSourceList() {
+	names := make([]string, 0, len(sourceRegistry))
+	for name := range sourceRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("Registered sources:")
+	for _, name := range names {
+		fmt.Printf("  %s\n", name)
+	}
+}
+
+// httpSource is the default http/https source: the single-page scrape
+// or, with --depth, the recursive crawler.
+type httpSource struct{}
+
+func (httpSource) Fetch(config Config, u *url.URL) ([]FileLink, error) {
+	config.URL = u.String()
+	return extractLinks(config)
+}
+
+// fileSource walks a local directory, honoring --ext/--all and
+// --include exactly like the http source does for remote links.
+type fileSource struct{}
+
+func (fileSource) Fetch(config Config, u *url.URL) ([]FileLink, error) {
+	root := u.Path
+	if root == "" {
+		root = u.Opaque
+	}
+	if root == "" {
+		return nil, fmt.Errorf("file source requires a path, e.g. file:///some/dir")
+	}
+
+	filter, err := newFileFilter(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []FileLink
+	err = filepath.Walk(root, func(path string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		if !filter.matches(path, path) {
+			return nil
+		}
+
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			abs = path
+		}
+
+		links = append(links, FileLink{Name: sanitizeFilename(filepath.Base(path)), URL: "file://" + abs})
+		return nil
+	})
+
+	return links, err
+}
@@ -0,0 +1,68 @@
+package main
+
+import "testing"
+
+func TestParseSourceURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		raw        string
+		wantKey    string
+		wantTarget string
+		wantErr    bool
+	}{
+		{"plain https", "https://example.com/page", "https", "https://example.com/page", false},
+		{"plain http", "http://example.com", "http", "http://example.com", false},
+		{"git double-colon prefix", "git::https://github.com/foo/bar", "git", "https://github.com/foo/bar", false},
+		{"sitemap plus prefix", "sitemap+https://example.com/sitemap.xml", "sitemap", "https://example.com/sitemap.xml", false},
+		{"file scheme", "file:///some/dir", "file", "file:///some/dir", false},
+		{"git scheme target keeps its own scheme", "git::ssh://git@example.com/foo.git", "git", "ssh://git@example.com/foo.git", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, target, err := parseSourceURL(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseSourceURL(%q) = nil error, want error", tt.raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSourceURL(%q) returned unexpected error: %v", tt.raw, err)
+			}
+			if key != tt.wantKey {
+				t.Errorf("parseSourceURL(%q) key = %q, want %q", tt.raw, key, tt.wantKey)
+			}
+			if target.String() != tt.wantTarget {
+				t.Errorf("parseSourceURL(%q) target = %q, want %q", tt.raw, target.String(), tt.wantTarget)
+			}
+		})
+	}
+}
+
+// TestGitSourceFetchRejectsFlagLikeURLs guards against the classic
+// git/go-getter argument-injection class (CVE-2017-1000117-style),
+// where a crafted "URL" round-trips through url.Parse/String into
+// something git's CLI parses as a flag instead of a repo.
+func TestGitSourceFetchRejectsFlagLikeURLs(t *testing.T) {
+	tests := []string{
+		"git::--upload-pack=touch /tmp/pwned;true",
+		"git::-oProxyCommand=touch /tmp/pwned",
+	}
+
+	for _, raw := range tests {
+		t.Run(raw, func(t *testing.T) {
+			key, target, err := parseSourceURL(raw)
+			if err != nil {
+				t.Fatalf("parseSourceURL(%q) returned unexpected error: %v", raw, err)
+			}
+			if key != "git" {
+				t.Fatalf("parseSourceURL(%q) key = %q, want \"git\"", raw, key)
+			}
+
+			if _, err := (gitSource{}).Fetch(Config{}, target); err == nil {
+				t.Errorf("gitSource.Fetch(%q) = nil error, want rejection of flag-like target", target.String())
+			}
+		})
+	}
+}